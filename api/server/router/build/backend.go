@@ -0,0 +1,16 @@
+package build // import "github.com/docker/docker/api/server/router/build"
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Backend abstracts an image build manager.
+type Backend interface {
+	// ImageBuild builds an image from a source given in the request and
+	// streams jsonmessage.JSONMessage values describing its progress to
+	// output.
+	ImageBuild(ctx context.Context, opts types.ImageBuildOptions, source io.Reader, output io.Writer) error
+}
@@ -0,0 +1,90 @@
+package build // import "github.com/docker/docker/api/server/router/build"
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/httputils"
+)
+
+type buildRouter struct {
+	backend Backend
+}
+
+// NewRouter initializes a new build router
+func NewRouter(backend Backend) *buildRouter {
+	return &buildRouter{backend: backend}
+}
+
+func (br *buildRouter) postBuild(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	options := types.ImageBuildOptions{
+		NoCache:     httputils.BoolValue(r, "nocache"),
+		Remove:      httputils.BoolValueOrDefault(r, "rm", true),
+		ForceRemove: httputils.BoolValue(r, "forcerm"),
+		Dockerfile:  r.FormValue("dockerfile"),
+		Tag:         r.FormValue("tag"),
+		Push:        httputils.BoolValue(r, "push"),
+	}
+	if tags := r.Form["t"]; len(tags) > 0 {
+		options.Tags = tags
+		if options.Tag == "" {
+			options.Tag = tags[0]
+		}
+	}
+	if options.Push && options.Tag == "" {
+		return errdefs.InvalidParameter(errors.New("tag is required when push=1"))
+	}
+	if platforms := r.FormValue("platforms"); platforms != "" {
+		options.Platforms = strings.Split(platforms, ",")
+	}
+
+	authConfigs, err := buildAuthConfigsFromHeader(r.Header.Get("X-Registry-Config"))
+	if err != nil {
+		return err
+	}
+	options.AuthConfigs = authConfigs
+
+	output := &flushWriter{ResponseWriter: w}
+	w.Header().Set("Content-Type", "application/json")
+	return br.backend.ImageBuild(r.Context(), options, r.Body, output)
+}
+
+type flushWriter struct {
+	http.ResponseWriter
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.ResponseWriter.Write(p)
+	if flusher, ok := f.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
+// buildAuthConfigsFromHeader decodes the `X-Registry-Config` header, a
+// base64-encoded JSON object of per-registry-hostname AuthConfig values,
+// the same format accepted by the existing image pull/push endpoints.
+func buildAuthConfigsFromHeader(header string) (map[string]types.AuthConfig, error) {
+	if header == "" {
+		return nil, nil
+	}
+	decoded, err := base64.URLEncoding.DecodeString(header)
+	if err != nil {
+		return nil, err
+	}
+	var configs map[string]types.AuthConfig
+	if err := json.NewDecoder(bytes.NewReader(decoded)).Decode(&configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
@@ -0,0 +1,14 @@
+package image // import "github.com/docker/docker/api/server/router/image"
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Backend abstracts an image inspection manager.
+type Backend interface {
+	// ImageInspect returns low-level information about an image by ID or
+	// reference.
+	ImageInspect(ctx context.Context, name string) (types.ImageInspect, error)
+}
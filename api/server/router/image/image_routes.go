@@ -0,0 +1,30 @@
+package image // import "github.com/docker/docker/api/server/router/image"
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type imageRouter struct {
+	backend Backend
+}
+
+// NewRouter initializes a new image router
+func NewRouter(backend Backend) *imageRouter {
+	return &imageRouter{backend: backend}
+}
+
+// getImagesByName handles `GET /images/{name}/json`, where name is
+// everything between the "/images/" and "/json" path segments so it can
+// itself contain slashes (e.g. "myrepo/myimage").
+func (ir *imageRouter) getImagesByName(w http.ResponseWriter, r *http.Request) error {
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/images/"), "/json")
+
+	info, err := ir.backend.ImageInspect(r.Context(), name)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(info)
+}
@@ -0,0 +1,102 @@
+package types // import "github.com/docker/docker/api/types"
+
+import (
+	"io"
+)
+
+// ImageBuildOptions holds the information necessary by a docker api client to build images.
+type ImageBuildOptions struct {
+	Tags           []string
+	SuppressOutput bool
+	RemoteContext  string
+	NoCache        bool
+	Remove         bool
+	ForceRemove    bool
+	PullParent     bool
+	Isolation      string
+	CPUSetCPUs     string
+	CPUSetMems     string
+	CPUShares      int64
+	CPUQuota       int64
+	CPUPeriod      int64
+	Memory         int64
+	MemorySwap     int64
+	CgroupParent   string
+	NetworkMode    string
+	ShmSize        int64
+	Dockerfile     string
+	Ulimits        []*Ulimit
+	// BuildArgs needs to be a *string instead of just a string so that
+	// we can distinguish between "" (empty string) and no value at all.
+	// In the parser/validator, we access the value as a pointer so it
+	// can be nil. This is because a nil pointer (value not set) and empty
+	// string value are different from each other.
+	BuildArgs  map[string]*string
+	AuthConfigs map[string]AuthConfig
+	Context     io.Reader
+	Labels      map[string]string
+	// squash the resulting image's layers to the parent
+	// preserves the original image and creates a new one from the parent with all
+	// the changes applied to a single layer
+	Squash bool
+	// CacheFrom specifies images that are used for matching cache. Images
+	// specified here do not need to have a valid parent chain to match cache.
+	CacheFrom   []string
+	SecurityOpt []string
+	ExtraHosts  []string // List of extra hosts
+	Target      string
+	SessionID   string
+	Platform    string
+	// Version specifies the version of the unerlying builder to use
+	Version BuilderVersion
+	// BuildID is an optional identifier that can be passed together with the
+	// build request. The same identifier can be used to gracefully cancel
+	// the build with the cancel request.
+	BuildID string
+	// Outputs defines configurations for exporting build results. Only supported
+	// in BuildKit mode
+	Outputs []ImageBuildOutput
+	// Push causes the daemon to push the resulting image to its registry
+	// once the build completes successfully. The daemon pushes to Tag, or to
+	// the first of Tags when Tag is empty, and rejects the build when both
+	// are empty. Credentials are taken from the AuthConfigs entry for the
+	// target registry; without one the push is sent unauthenticated.
+	Push bool
+	// Tag is the reference the built image is pushed as when Push is set.
+	Tag string
+	// ProgressWriter, if set, receives the build's structured per-step
+	// progress (jsonmessage.BuildVertex aux events) rendered live, instead
+	// of requiring the caller to parse the raw JSON stream itself. It is
+	// consumed client-side only and is never sent to the daemon.
+	ProgressWriter io.Writer
+	// Platforms requests that the Dockerfile be built once per listed
+	// platform (e.g. "linux/amd64", "linux/arm64"). When more than one
+	// platform is given, the daemon assembles the resulting images into a
+	// single manifest list instead of a plain image. Mutually exclusive
+	// with Platform.
+	Platforms []string
+}
+
+// ImageBuildOutput defines configuration for exporting a build result
+type ImageBuildOutput struct {
+	Type  string
+	Attrs map[string]string
+}
+
+// BuilderVersion describes the version of underlying builder to use
+type BuilderVersion string
+
+const (
+	// BuilderV1 is the first generation builder in docker daemon
+	BuilderV1 BuilderVersion = "1"
+	// BuilderBuildKit is builder based on moby/buildkit project
+	BuilderBuildKit = "2"
+)
+
+// ImageBuildResponse holds information
+// returned by a server after building
+// an image.
+type ImageBuildResponse struct {
+	Body   io.ReadCloser
+	OSType string
+}
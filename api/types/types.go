@@ -0,0 +1,72 @@
+package types // import "github.com/docker/docker/api/types"
+
+// AuthConfig contains authorization information for connecting to a Registry.
+type AuthConfig struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	Auth string `json:"auth,omitempty"`
+
+	// Email is an optional value associated with the username.
+	// This field is deprecated and will be removed in a later
+	// version of docker.
+	Email string `json:"email,omitempty"`
+
+	ServerAddress string `json:"serveraddress,omitempty"`
+
+	// IdentityToken is used to authenticate the user and get
+	// an access token for the registry.
+	IdentityToken string `json:"identitytoken,omitempty"`
+
+	// RegistryToken is a bearer token to be sent to a registry
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// Ulimit is a human friendly version of Linux's ulimit.
+type Ulimit struct {
+	Name string `json:"Name"`
+	Hard int64  `json:"Hard"`
+	Soft int64  `json:"Soft"`
+}
+
+// BuildPushResult is the final `aux` message emitted on a single-platform
+// build's JSON stream when `ImageBuildOptions.Push` is set, carrying the
+// registry manifest digest of the image the daemon pushed on the caller's
+// behalf. A multi-platform build does not emit it; the pushed list's digest
+// is reported in BuildManifestListResult.ListDigest instead.
+type BuildPushResult struct {
+	ID     string `json:"ID"`
+	Digest string `json:"Digest"`
+	Tag    string `json:"Tag"`
+}
+
+// BuildManifestEntry identifies the per-platform image produced by one leg
+// of a multi-platform build.
+type BuildManifestEntry struct {
+	Platform string `json:"platform"`
+	ID       string `json:"id"`
+}
+
+// BuildManifestListResult is the final `aux` message emitted on a build's
+// JSON stream when `ImageBuildOptions.Platforms` names more than one
+// platform, describing the manifest list the daemon assembled from each
+// platform's image.
+type BuildManifestListResult struct {
+	Manifests  []BuildManifestEntry `json:"manifests"`
+	ListDigest string               `json:"listDigest"`
+}
+
+// ImageConfig holds the subset of an image's config that ImageInspect
+// exposes.
+type ImageConfig struct {
+	// Labels are the config labels set on the image, including any
+	// persisted by the build (e.g. the buildinfo provenance label).
+	Labels map[string]string `json:"Labels"`
+}
+
+// ImageInspect is the response to ImageInspect, describing an image by ID
+// or reference.
+type ImageInspect struct {
+	ID     string       `json:"Id"`
+	Config *ImageConfig `json:"Config"`
+}
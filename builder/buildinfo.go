@@ -0,0 +1,50 @@
+package builder // import "github.com/docker/docker/builder"
+
+import (
+	"sync"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// BuildInfoRecorder accumulates the provenance of a single build as the
+// builder resolves each `FROM`, remote `ADD`, and git context, so the final
+// manifest can be emitted without a second pass over the Dockerfile.
+type BuildInfoRecorder struct {
+	mu      sync.Mutex
+	sources []jsonmessage.BuildInfoSource
+}
+
+// NewBuildInfoRecorder returns an empty recorder for a new build.
+func NewBuildInfoRecorder() *BuildInfoRecorder {
+	return &BuildInfoRecorder{}
+}
+
+// AddImageSource records the resolved digest of a `FROM` image.
+func (r *BuildInfoRecorder) AddImageSource(ref, digest string) {
+	r.add(jsonmessage.BuildInfoSource{Type: "docker-image", Ref: ref, Pin: digest})
+}
+
+// AddHTTPSource records the resolved content digest of a remote `ADD <url>`.
+func (r *BuildInfoRecorder) AddHTTPSource(url, digest string) {
+	r.add(jsonmessage.BuildInfoSource{Type: "http", Ref: url, Pin: digest})
+}
+
+// AddGitSource records the resolved commit SHA of a git build context.
+func (r *BuildInfoRecorder) AddGitSource(url, commit string) {
+	r.add(jsonmessage.BuildInfoSource{Type: "git", Ref: url, Pin: commit})
+}
+
+func (r *BuildInfoRecorder) add(s jsonmessage.BuildInfoSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, s)
+}
+
+// BuildInfo returns the accumulated provenance manifest for the build.
+func (r *BuildInfoRecorder) BuildInfo() jsonmessage.BuildInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sources := make([]jsonmessage.BuildInfoSource, len(r.sources))
+	copy(sources, r.sources)
+	return jsonmessage.BuildInfo{Sources: sources}
+}
@@ -0,0 +1,72 @@
+package builder // import "github.com/docker/docker/builder"
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ProgressRecorder streams structured per-step build progress as
+// jsonmessage.BuildVertex aux events, so clients can track step start/finish,
+// intermediate container IDs, and cache hits without scraping `Stream` text.
+type ProgressRecorder struct {
+	enc *json.Encoder
+}
+
+// NewProgressRecorder returns a recorder that writes vertex events to out.
+func NewProgressRecorder(out io.Writer) *ProgressRecorder {
+	return &ProgressRecorder{enc: json.NewEncoder(out)}
+}
+
+// StepStarted emits a vertex event marking the start of step id ("stage-0/step-2").
+func (p *ProgressRecorder) StepStarted(id, name string) error {
+	return p.emit(jsonmessage.BuildVertex{Type: "vertex", ID: id, Name: name, Started: now()})
+}
+
+// ContainerStarted emits a vertex event reporting the intermediate
+// container a running step was given, distinct from the step's eventual
+// completion so callers (and the legacy stream adapter) can tell "now
+// running in this container" apart from "step is done".
+func (p *ProgressRecorder) ContainerStarted(id, name, containerID string) error {
+	return p.emit(jsonmessage.BuildVertex{Type: "vertex", ID: id, Name: name, ContainerID: containerID})
+}
+
+// StepCompleted emits a vertex event marking a step's successful completion,
+// recording the intermediate container it ran in and whether it was cached.
+func (p *ProgressRecorder) StepCompleted(id, name, containerID string, cached bool) error {
+	return p.emit(jsonmessage.BuildVertex{
+		Type:        "vertex",
+		ID:          id,
+		Name:        name,
+		Completed:   now(),
+		ContainerID: containerID,
+		Cached:      cached,
+	})
+}
+
+// StepFailed emits a vertex event marking a step's failure.
+func (p *ProgressRecorder) StepFailed(id, name, containerID string, stepErr error) error {
+	return p.emit(jsonmessage.BuildVertex{
+		Type:        "vertex",
+		ID:          id,
+		Name:        name,
+		Completed:   now(),
+		ContainerID: containerID,
+		Error:       stepErr.Error(),
+	})
+}
+
+func (p *ProgressRecorder) emit(v jsonmessage.BuildVertex) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	rawMsg := json.RawMessage(raw)
+	return p.enc.Encode(jsonmessage.JSONMessage{Aux: &rawMsg})
+}
+
+func now() string {
+	return time.Now().Format(time.RFC3339Nano)
+}
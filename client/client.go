@@ -0,0 +1,38 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"net/http"
+	"os"
+)
+
+// Client is the Go SDK's HTTP client for the Engine API.
+type Client struct {
+	client  *http.Client
+	baseURL string
+}
+
+// Opt configures a Client constructed by NewClientWithOpts.
+type Opt func(*Client) error
+
+// NewClientWithOpts returns a Client configured by the given Opts.
+func NewClientWithOpts(opts ...Opt) (*Client, error) {
+	cli := &Client{
+		client:  &http.Client{},
+		baseURL: "http://localhost:2375",
+	}
+	for _, opt := range opts {
+		if err := opt(cli); err != nil {
+			return nil, err
+		}
+	}
+	return cli, nil
+}
+
+// FromEnv configures the client from the same DOCKER_HOST environment
+// variable the docker CLI honors.
+func FromEnv(cli *Client) error {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		cli.baseURL = host
+	}
+	return nil
+}
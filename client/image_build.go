@@ -0,0 +1,97 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ImageBuild sends a build context to the daemon and returns the resulting
+// JSON message stream. If options.ProgressWriter is set, the stream's
+// structured vertex events are also rendered live to it via
+// jsonmessage.DisplayBuildProgress, so callers don't have to parse the raw
+// stream themselves just to watch progress.
+func (cli *Client) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	query := imageBuildQuery(options)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cli.baseURL+"/build?"+query.Encode(), buildContext)
+	if err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+
+	if len(options.AuthConfigs) > 0 {
+		header, err := registryConfigHeader(options.AuthConfigs)
+		if err != nil {
+			return types.ImageBuildResponse{}, err
+		}
+		req.Header.Set("X-Registry-Config", header)
+	}
+
+	resp, err := cli.client.Do(req)
+	if err != nil {
+		return types.ImageBuildResponse{}, err
+	}
+
+	body := resp.Body
+	if options.ProgressWriter != nil {
+		body = tee(body, options.ProgressWriter)
+	}
+
+	return types.ImageBuildResponse{Body: body, OSType: resp.Header.Get("Ostype")}, nil
+}
+
+// tee wraps in so that everything read from the returned ReadCloser is also
+// rendered live to progress as structured build progress, while still
+// passing the original JSON stream through to the caller untouched.
+func tee(in io.ReadCloser, progress io.Writer) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer in.Close()
+		err := jsonmessage.DisplayBuildProgress(progress, io.TeeReader(in, pw))
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+func imageBuildQuery(options types.ImageBuildOptions) url.Values {
+	query := url.Values{}
+	if options.Dockerfile != "" {
+		query.Set("dockerfile", options.Dockerfile)
+	}
+	query.Set("rm", strconv.FormatBool(options.Remove))
+	query.Set("forcerm", strconv.FormatBool(options.ForceRemove))
+	query.Set("nocache", strconv.FormatBool(options.NoCache))
+	for _, tag := range options.Tags {
+		query.Add("t", tag)
+	}
+	if options.Push {
+		query.Set("push", "1")
+	}
+	if options.Tag != "" {
+		query.Set("tag", options.Tag)
+	}
+	if len(options.Platforms) > 0 {
+		query.Set("platforms", strings.Join(options.Platforms, ","))
+	}
+	return query
+}
+
+// registryConfigHeader base64-encodes configs the same way the daemon's
+// buildAuthConfigsFromHeader decodes the `X-Registry-Config` header: a JSON
+// object keyed by registry hostname.
+func registryConfigHeader(configs map[string]types.AuthConfig) (string, error) {
+	raw, err := json.Marshal(configs)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(raw), nil
+}
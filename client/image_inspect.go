@@ -0,0 +1,36 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ImageInspectWithRaw returns low-level information about an image by ID or
+// reference, along with the raw JSON the daemon returned it in.
+func (cli *Client) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cli.baseURL+"/images/"+imageID+"/json", nil)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	resp, err := cli.client.Do(req)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+
+	var info types.ImageInspect
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return types.ImageInspect{}, nil, err
+	}
+	return info, raw, nil
+}
@@ -0,0 +1,174 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/builder"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// defaultRegistryHostname is the key AuthConfigs uses for the default
+// registry, matching the convention the existing pull/push endpoints use
+// for unqualified references (e.g. "busybox", "library/busybox"): Docker
+// Hub's credential entries are keyed by its v1 index address, not by the
+// "docker.io" hostname that appears in qualified references.
+const defaultRegistryHostname = "https://index.docker.io/v1/"
+
+// buildInfoLabel is the image config label under which the provenance
+// manifest produced by a build is persisted, so that ImageInspect can
+// return it without re-running the build.
+const buildInfoLabel = "moby.buildinfo.v1"
+
+// ImageBuild builds an image from a Source and driving options, streaming
+// progress as newline-delimited jsonmessage.JSONMessage values to output.
+// When opts.Platforms names more than one platform, the Dockerfile is built
+// once per platform and the results are assembled into a manifest list.
+func (daemon *Daemon) ImageBuild(ctx context.Context, opts types.ImageBuildOptions, source io.Reader, output io.Writer) error {
+	if len(opts.Platforms) > 1 {
+		return daemon.buildMultiPlatform(ctx, opts, source, output)
+	}
+	if len(opts.Platforms) == 1 {
+		opts.Platform = opts.Platforms[0]
+		opts.Platforms = nil
+	}
+	_, err := daemon.buildSinglePlatform(ctx, opts, source, output)
+	return err
+}
+
+// buildSinglePlatform runs one Dockerfile build to completion, emits the
+// usual status/buildinfo/push messages, and returns the resulting image ID.
+func (daemon *Daemon) buildSinglePlatform(ctx context.Context, opts types.ImageBuildOptions, source io.Reader, output io.Writer) (string, error) {
+	enc := json.NewEncoder(output)
+	recorder := builder.NewBuildInfoRecorder()
+	progress := builder.NewProgressRecorder(output)
+
+	imageID, err := daemon.buildFromContext(ctx, opts, source, recorder, progress)
+	if err != nil {
+		return "", err
+	}
+
+	info := recorder.BuildInfo()
+	if err := daemon.persistBuildInfoLabel(imageID, info); err != nil {
+		return "", err
+	}
+
+	if err := enc.Encode(jsonmessage.JSONMessage{
+		Status:    "Successfully built " + imageID,
+		BuildInfo: &info,
+	}); err != nil {
+		return "", err
+	}
+
+	if opts.Push {
+		if err := daemon.pushBuildResult(ctx, opts, imageID, enc); err != nil {
+			return "", err
+		}
+	}
+	return imageID, nil
+}
+
+// buildMultiPlatform builds opts.Dockerfile once per requested platform and
+// assembles the resulting images into a manifest list, storing it locally
+// so ImageInspect/ImagePush can operate on it like any other image
+// reference.
+func (daemon *Daemon) buildMultiPlatform(ctx context.Context, opts types.ImageBuildOptions, source io.Reader, output io.Writer) error {
+	// The build context is a one-shot tar stream; buffer it so it can be
+	// replayed for each platform leg.
+	raw, err := io.ReadAll(source)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(output)
+	manifests := make([]types.BuildManifestEntry, 0, len(opts.Platforms))
+	for _, platform := range opts.Platforms {
+		legOpts := opts
+		legOpts.Platform = platform
+		legOpts.Platforms = nil
+		// Each leg produces one platform's image under the shared tag;
+		// pushing it here would have every platform overwrite the last
+		// one's manifest in the registry. Push the assembled list once,
+		// below, instead.
+		legOpts.Push = false
+
+		imageID, err := daemon.buildSinglePlatform(ctx, legOpts, bytes.NewReader(raw), output)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, types.BuildManifestEntry{Platform: platform, ID: imageID})
+	}
+
+	listDigest, err := daemon.storeManifestList(opts.Tag, manifests)
+	if err != nil {
+		return err
+	}
+
+	if opts.Push {
+		authConfig := opts.AuthConfigs[registryHostname(opts.Tag)]
+		pushedDigest, err := daemon.pushManifestList(ctx, opts.Tag, manifests, authConfig)
+		if err != nil {
+			return err
+		}
+		listDigest = pushedDigest
+	}
+
+	aux, err := json.Marshal(types.BuildManifestListResult{Manifests: manifests, ListDigest: listDigest})
+	if err != nil {
+		return err
+	}
+	rawAux := json.RawMessage(aux)
+	return enc.Encode(jsonmessage.JSONMessage{Aux: &rawAux})
+}
+
+// pushBuildResult pushes the freshly built image to its registry and emits
+// an aux message carrying the resulting manifest digest, so callers don't
+// need a separate ImagePush/ImageInspect round trip to learn it.
+func (daemon *Daemon) pushBuildResult(ctx context.Context, opts types.ImageBuildOptions, imageID string, enc *json.Encoder) error {
+	authConfig := opts.AuthConfigs[registryHostname(opts.Tag)]
+	digest, err := daemon.pushImage(ctx, opts.Tag, imageID, authConfig)
+	if err != nil {
+		return err
+	}
+
+	aux, err := json.Marshal(types.BuildPushResult{ID: imageID, Digest: digest, Tag: opts.Tag})
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(aux)
+	return enc.Encode(jsonmessage.JSONMessage{Aux: &raw})
+}
+
+// registryHostname extracts the registry server address from an image
+// reference, the same key buildAuthConfigsFromHeader decodes
+// X-Registry-Config's per-registry AuthConfig map under. A reference's
+// first "/"-separated component is a registry host only if it looks like
+// one (contains a "." or ":", or is exactly "localhost"); otherwise the
+// reference is unqualified and belongs to the default registry, keyed as
+// defaultRegistryHostname.
+func registryHostname(ref string) string {
+	i := strings.IndexRune(ref, '/')
+	if i == -1 {
+		return defaultRegistryHostname
+	}
+	host := ref[:i]
+	if host == "localhost" || strings.ContainsAny(host, ".:") {
+		return host
+	}
+	return defaultRegistryHostname
+}
+
+// persistBuildInfoLabel stores the base64-encoded provenance manifest as an
+// image config label so it survives independently of the build stream.
+func (daemon *Daemon) persistBuildInfoLabel(imageID string, info jsonmessage.BuildInfo) error {
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return daemon.setImageLabel(imageID, buildInfoLabel, base64.StdEncoding.EncodeToString(raw))
+}
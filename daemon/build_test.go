@@ -0,0 +1,55 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryHostname(t *testing.T) {
+	cases := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"unqualified repo", "busybox", defaultRegistryHostname},
+		{"unqualified namespaced repo", "library/busybox", defaultRegistryHostname},
+		{"host with port", "registry.example.com:5000/myimage", "registry.example.com:5000"},
+		{"host with dot, no port", "registry.example.com/myimage", "registry.example.com"},
+		{"localhost", "localhost/myimage", "localhost"},
+		{"localhost with port", "localhost:5000/myimage", "localhost:5000"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, registryHostname(tc.ref))
+		})
+	}
+}
+
+// TestPushBuildResultUsesAuthConfigsKeyedByRegistryHostname verifies the
+// exact lookup pushBuildResult performs (opts.AuthConfigs[registryHostname(opts.Tag)])
+// for an unqualified, Hub-style tag: the caller's AuthConfigs entry must be
+// keyed "https://index.docker.io/v1/", Docker's real auth-config convention
+// for the default registry, not the "docker.io" hostname that appears in a
+// qualified reference.
+func TestPushBuildResultUsesAuthConfigsKeyedByRegistryHostname(t *testing.T) {
+	want := types.AuthConfig{Username: "hub-user", Password: "hub-token"}
+	opts := types.ImageBuildOptions{
+		Tag: "myname/myimage",
+		AuthConfigs: map[string]types.AuthConfig{
+			"https://index.docker.io/v1/": want,
+		},
+	}
+
+	got, ok := opts.AuthConfigs[registryHostname(opts.Tag)]
+	require.True(t, ok, "expected the Hub auth entry to be found under %q", defaultRegistryHostname)
+	require.Equal(t, want, got)
+
+	// A "docker.io" key, as would be used if the default registry were
+	// keyed by hostname instead, must NOT be what's consulted.
+	_, wrongKeyFound := opts.AuthConfigs["docker.io"]
+	require.False(t, wrongKeyFound)
+}
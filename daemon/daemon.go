@@ -0,0 +1,88 @@
+package daemon // import "github.com/docker/docker/daemon"
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/builder"
+)
+
+// Daemon holds the dependencies ImageBuild and its helpers drive: the
+// builder that actually resolves stages and external sources, the image
+// service that persists the resulting config, the registry client used to
+// push build results, and the manifest list store backing multi-platform
+// builds.
+type Daemon struct {
+	imageService  imageBackend
+	builder       builderBackend
+	registry      registryBackend
+	manifestStore manifestListBackend
+}
+
+// imageBackend is the subset of the image service ImageBuild needs to
+// persist build-time metadata onto an already-created image, and
+// ImageInspect needs to read it back.
+type imageBackend interface {
+	// SetLabel adds or overwrites a config label on imageID.
+	SetLabel(imageID, key, value string) error
+	// Labels returns the config labels set on imageID, as persisted by
+	// SetLabel.
+	Labels(imageID string) (map[string]string, error)
+}
+
+// builderBackend is the subset of the builder ImageBuild drives: running a
+// Dockerfile to completion against a build context, reporting progress and
+// recording provenance as it resolves each stage and external source.
+type builderBackend interface {
+	Build(ctx context.Context, opts types.ImageBuildOptions, source io.Reader, info *builder.BuildInfoRecorder, progress *builder.ProgressRecorder) (string, error)
+}
+
+// registryBackend is the subset of the registry client ImageBuild needs to
+// push a build's result.
+type registryBackend interface {
+	// PushImage pushes imageID to ref using auth, returning the manifest
+	// digest the registry assigned it.
+	PushImage(ctx context.Context, ref, imageID string, auth types.AuthConfig) (digest string, err error)
+	// PushManifestList pushes an already-assembled manifest list covering
+	// entries to ref using auth, returning the list's manifest digest.
+	PushManifestList(ctx context.Context, ref string, entries []types.BuildManifestEntry, auth types.AuthConfig) (digest string, err error)
+}
+
+// manifestListBackend stores manifest lists produced by multi-platform
+// builds locally, so ImageInspect/ImagePush can operate on the list
+// reference like any other image.
+type manifestListBackend interface {
+	// StoreManifestList records a manifest list for ref over entries and
+	// returns its local digest.
+	StoreManifestList(ref string, entries []types.BuildManifestEntry) (digest string, err error)
+}
+
+// buildFromContext hands the build context off to the builder, returning
+// the resulting image ID.
+func (daemon *Daemon) buildFromContext(ctx context.Context, opts types.ImageBuildOptions, source io.Reader, info *builder.BuildInfoRecorder, progress *builder.ProgressRecorder) (string, error) {
+	return daemon.builder.Build(ctx, opts, source, info, progress)
+}
+
+// setImageLabel persists a config label on an already-built image.
+func (daemon *Daemon) setImageLabel(imageID, key, value string) error {
+	return daemon.imageService.SetLabel(imageID, key, value)
+}
+
+// pushImage pushes imageID to ref and returns the digest the registry
+// reports for it.
+func (daemon *Daemon) pushImage(ctx context.Context, ref, imageID string, auth types.AuthConfig) (string, error) {
+	return daemon.registry.PushImage(ctx, ref, imageID, auth)
+}
+
+// pushManifestList pushes the assembled manifest list for a multi-platform
+// build and returns the digest the registry assigned it.
+func (daemon *Daemon) pushManifestList(ctx context.Context, ref string, entries []types.BuildManifestEntry, auth types.AuthConfig) (string, error) {
+	return daemon.registry.PushManifestList(ctx, ref, entries, auth)
+}
+
+// storeManifestList records a multi-platform build's manifest list locally
+// and returns its digest.
+func (daemon *Daemon) storeManifestList(ref string, entries []types.BuildManifestEntry) (string, error) {
+	return daemon.manifestStore.StoreManifestList(ref, entries)
+}
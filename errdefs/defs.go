@@ -0,0 +1,35 @@
+package errdefs // import "github.com/docker/docker/errdefs"
+
+import "errors"
+
+// ErrInvalidParameter signals that the user input is invalid, and is
+// checked for by API handlers that need to translate it into a 400
+// response.
+type ErrInvalidParameter interface {
+	InvalidParameter()
+}
+
+type errInvalidParameter struct{ error }
+
+func (e errInvalidParameter) InvalidParameter() {}
+
+func (e errInvalidParameter) Cause() error { return e.error }
+
+func (e errInvalidParameter) Unwrap() error { return e.error }
+
+// InvalidParameter wraps err so that errors.As/IsInvalidParameter recognize
+// it as a validation error of the request, the same sentinel used by every
+// other 400-class error in the API.
+func InvalidParameter(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errInvalidParameter{err}
+}
+
+// IsInvalidParameter returns true if the error is, or wraps, an
+// ErrInvalidParameter.
+func IsInvalidParameter(err error) bool {
+	var target ErrInvalidParameter
+	return errors.As(err, &target)
+}
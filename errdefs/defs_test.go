@@ -0,0 +1,18 @@
+package errdefs // import "github.com/docker/docker/errdefs"
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsInvalidParameter(t *testing.T) {
+	err := InvalidParameter(fmt.Errorf("bad input"))
+	require.True(t, IsInvalidParameter(err))
+
+	wrapped := fmt.Errorf("context: %w", err)
+	require.True(t, IsInvalidParameter(wrapped), "a wrapped InvalidParameter error should still be recognized")
+
+	require.False(t, IsInvalidParameter(fmt.Errorf("unrelated")))
+}
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/integration/util/request"
+	"github.com/docker/docker/internal/test/registry"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/stretchr/testify/require"
 )
@@ -109,8 +111,424 @@ func TestBuildWithRemoveAndForceRemove(t *testing.T) {
 	}
 }
 
+// TestBuildEmitsStructuredProgress verifies that each RUN step in a build
+// produces a started and a completed vertex event carrying its intermediate
+// container ID, so CI tools can attribute progress and failures without
+// scraping `Stream` text.
+func TestBuildEmitsStructuredProgress(t *testing.T) {
+	t.Parallel()
+	dockerfile := []byte(`FROM busybox
+	RUN exit 0
+	RUN exit 0`)
+
+	buff := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buff)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "Dockerfile",
+		Size: int64(len(dockerfile)),
+	}))
+	_, err := tw.Write(dockerfile)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+	resp, err := client.ImageBuild(ctx, buff, types.ImageBuildOptions{NoCache: true})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	started := map[string]bool{}
+	completed := map[string]string{}
+	dec := json.NewDecoder(resp.Body)
+	for {
+		m := jsonmessage.JSONMessage{}
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		v, ok := jsonmessage.VertexFromAux(m)
+		if !ok {
+			continue
+		}
+		if v.Started != "" {
+			started[v.ID] = true
+		}
+		if v.Completed != "" {
+			completed[v.ID] = v.ContainerID
+		}
+	}
+
+	require.Len(t, started, 2, "expected a started event for each RUN step")
+	require.Len(t, completed, 2, "expected a completed event for each RUN step")
+	for id := range started {
+		containerID, ok := completed[id]
+		require.True(t, ok, "step %s never completed", id)
+		require.NotEmpty(t, containerID, "step %s completed without an intermediate container ID", id)
+	}
+}
+
+// TestBuildEmitsBuildInfo verifies that a successful build ends its JSON
+// stream with a buildinfo message listing every external source the build
+// consumed, so callers get a reproducible manifest without re-scanning the
+// Dockerfile themselves.
+func TestBuildEmitsBuildInfo(t *testing.T) {
+	t.Parallel()
+	dockerfile := []byte(`FROM busybox
+	ADD https://raw.githubusercontent.com/moby/moby/master/README.md /README.md`)
+
+	buff := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buff)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "Dockerfile",
+		Size: int64(len(dockerfile)),
+	}))
+	_, err := tw.Write(dockerfile)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+	resp, err := client.ImageBuild(ctx, buff, types.ImageBuildOptions{NoCache: true})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	info, err := buildInfoFromStream(resp.Body)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+
+	var sawImage, sawHTTP bool
+	for _, src := range info.Sources {
+		switch src.Type {
+		case "docker-image":
+			require.Equal(t, "busybox", src.Ref)
+			require.NotEmpty(t, src.Pin)
+			sawImage = true
+		case "http":
+			require.Contains(t, src.Ref, "README.md")
+			require.NotEmpty(t, src.Pin)
+			sawHTTP = true
+		}
+	}
+	require.True(t, sawImage, "expected buildinfo to record the FROM image")
+	require.True(t, sawHTTP, "expected buildinfo to record the ADD url")
+}
+
+func buildInfoFromStream(buildOutput io.Reader) (*jsonmessage.BuildInfo, error) {
+	dec := json.NewDecoder(buildOutput)
+	for {
+		m := jsonmessage.JSONMessage{}
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.BuildInfo != nil {
+			return m.BuildInfo, nil
+		}
+	}
+}
+
+// TestBuildPersistsBuildInfoLabel verifies that the buildinfo provenance
+// manifest emitted on the build's JSON stream is also persisted as the
+// moby.buildinfo.v1 image config label, so ImageInspect can return it
+// without re-running or re-scanning the build.
+func TestBuildPersistsBuildInfoLabel(t *testing.T) {
+	t.Parallel()
+	dockerfile := []byte(`FROM busybox
+	ADD https://raw.githubusercontent.com/moby/moby/master/README.md /README.md`)
+
+	buff := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buff)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "Dockerfile",
+		Size: int64(len(dockerfile)),
+	}))
+	_, err := tw.Write(dockerfile)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+	resp, err := client.ImageBuild(ctx, buff, types.ImageBuildOptions{NoCache: true})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	imageID, info, err := buildResultFromStream(resp.Body)
+	require.NoError(t, err)
+	require.NotEmpty(t, imageID)
+	require.NotNil(t, info)
+
+	inspect, _, err := client.ImageInspectWithRaw(ctx, imageID)
+	require.NoError(t, err)
+	require.NotNil(t, inspect.Config)
+
+	// buildInfoLabel in daemon/build.go.
+	const buildInfoLabel = "moby.buildinfo.v1"
+	encoded, ok := inspect.Config.Labels[buildInfoLabel]
+	require.True(t, ok, "expected the built image to carry the %s label", buildInfoLabel)
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	var labelInfo jsonmessage.BuildInfo
+	require.NoError(t, json.Unmarshal(raw, &labelInfo))
+	require.Equal(t, *info, labelInfo, "the persisted label must match the buildinfo emitted on the stream")
+}
+
+// buildResultFromStream returns the built image's ID and buildinfo manifest
+// from the JSONMessage that reports the build succeeded.
+func buildResultFromStream(buildOutput io.Reader) (string, *jsonmessage.BuildInfo, error) {
+	dec := json.NewDecoder(buildOutput)
+	for {
+		m := jsonmessage.JSONMessage{}
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			return "", nil, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if m.BuildInfo != nil {
+			return strings.TrimPrefix(m.Status, "Successfully built "), m.BuildInfo, nil
+		}
+	}
+}
+
+// TestBuildWithPush verifies that a build started with Push=true pushes the
+// resulting image and reports the registry's manifest digest in an aux
+// message, so callers don't need a follow-up ImagePush/ImageInspect to learn it.
+func TestBuildWithPush(t *testing.T) {
+	t.Parallel()
+	reg := registry.NewV2(t)
+	defer reg.Close(t)
+
+	repo := reg.URL + "/build-push-test"
+	dockerfile := []byte(`FROM busybox
+	RUN echo hello`)
+
+	buff := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buff)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "Dockerfile",
+		Size: int64(len(dockerfile)),
+	}))
+	_, err := tw.Write(dockerfile)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+	resp, err := client.ImageBuild(ctx, buff, types.ImageBuildOptions{
+		NoCache: true,
+		Tags:    []string{repo},
+		Tag:     repo,
+		Push:    true,
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	result, err := pushResultFromStream(resp.Body)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, repo, result.Tag)
+
+	wantDigest, err := reg.ManifestDigest("build-push-test", "latest")
+	require.NoError(t, err)
+	require.Equal(t, wantDigest, result.Digest, "pushed digest reported by the build must match what the registry has")
+}
+
+func pushResultFromStream(buildOutput io.Reader) (*types.BuildPushResult, error) {
+	dec := json.NewDecoder(buildOutput)
+	for {
+		m := jsonmessage.JSONMessage{}
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.Aux == nil {
+			continue
+		}
+		var result types.BuildPushResult
+		if err := json.Unmarshal(*m.Aux, &result); err != nil {
+			return nil, err
+		}
+		if result.Digest != "" {
+			return &result, nil
+		}
+	}
+}
+
+// TestBuildMultiPlatformWithRemoveAndForceRemove builds the same Dockerfile
+// once per requested platform and verifies intermediate containers from
+// every platform leg are cleaned up according to the rm/forceRm flags, the
+// same contract TestBuildWithRemoveAndForceRemove checks for a single
+// platform build.
+func TestBuildMultiPlatformWithRemoveAndForceRemove(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name                           string
+		dockerfile                     string
+		numberOfIntermediateContainers int
+		rm                             bool
+		forceRm                        bool
+	}{
+		{
+			name: "successful multi-platform build with no removal",
+			dockerfile: `FROM busybox
+			RUN exit 0`,
+			numberOfIntermediateContainers: 2, // one per platform
+			rm:      false,
+			forceRm: false,
+		},
+		{
+			name: "successful multi-platform build with remove",
+			dockerfile: `FROM busybox
+			RUN exit 0`,
+			numberOfIntermediateContainers: 0,
+			rm:      true,
+			forceRm: false,
+		},
+	}
+
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+	platforms := []string{"linux/amd64", "linux/arm64"}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			dockerfile := []byte(c.dockerfile)
+
+			buff := bytes.NewBuffer(nil)
+			tw := tar.NewWriter(buff)
+			require.NoError(t, tw.WriteHeader(&tar.Header{
+				Name: "Dockerfile",
+				Size: int64(len(dockerfile)),
+			}))
+			_, err := tw.Write(dockerfile)
+			require.NoError(t, err)
+			require.NoError(t, tw.Close())
+
+			resp, err := client.ImageBuild(ctx, buff, types.ImageBuildOptions{
+				Remove:      c.rm,
+				ForceRemove: c.forceRm,
+				NoCache:     true,
+				Platforms:   platforms,
+			})
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var manifestList *types.BuildManifestListResult
+			dec := json.NewDecoder(resp.Body)
+			filter := filters.NewArgs()
+			for {
+				m := jsonmessage.JSONMessage{}
+				err := dec.Decode(&m)
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				if v, ok := jsonmessage.VertexFromAux(m); ok && v.ContainerID != "" {
+					filter.Add("id", v.ContainerID)
+					continue
+				}
+				if m.Aux == nil {
+					continue
+				}
+				var list types.BuildManifestListResult
+				if err := json.Unmarshal(*m.Aux, &list); err == nil && len(list.Manifests) > 0 {
+					manifestList = &list
+				}
+			}
+
+			require.NotNil(t, manifestList)
+			require.Len(t, manifestList.Manifests, len(platforms))
+			require.NotEmpty(t, manifestList.ListDigest)
+
+			remainingContainers, err := client.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+			require.NoError(t, err)
+			require.Equal(t, c.numberOfIntermediateContainers, len(remainingContainers), "Expected %v remaining intermediate containers across all platform builds, got %v", c.numberOfIntermediateContainers, len(remainingContainers))
+		})
+	}
+}
+
+// TestBuildMultiPlatformWithPush verifies that a multi-platform build with
+// Push=true pushes the assembled manifest list exactly once, rather than
+// having each platform leg push its single-arch image to the shared tag
+// and clobber the previous leg's manifest.
+func TestBuildMultiPlatformWithPush(t *testing.T) {
+	t.Parallel()
+	reg := registry.NewV2(t)
+	defer reg.Close(t)
+
+	repo := reg.URL + "/build-multiplatform-push-test"
+	dockerfile := []byte(`FROM busybox
+	RUN echo hello`)
+
+	buff := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buff)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "Dockerfile",
+		Size: int64(len(dockerfile)),
+	}))
+	_, err := tw.Write(dockerfile)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	client := request.NewAPIClient(t)
+	ctx := context.Background()
+	platforms := []string{"linux/amd64", "linux/arm64"}
+	resp, err := client.ImageBuild(ctx, buff, types.ImageBuildOptions{
+		NoCache:   true,
+		Tags:      []string{repo},
+		Tag:       repo,
+		Push:      true,
+		Platforms: platforms,
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var manifestList *types.BuildManifestListResult
+	var pushResults []types.BuildPushResult
+	dec := json.NewDecoder(resp.Body)
+	for {
+		m := jsonmessage.JSONMessage{}
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if m.Aux == nil {
+			continue
+		}
+		var list types.BuildManifestListResult
+		if err := json.Unmarshal(*m.Aux, &list); err == nil && len(list.Manifests) > 0 {
+			manifestList = &list
+			continue
+		}
+		var push types.BuildPushResult
+		if err := json.Unmarshal(*m.Aux, &push); err == nil && push.Digest != "" {
+			pushResults = append(pushResults, push)
+		}
+	}
+
+	require.NotNil(t, manifestList)
+	require.Len(t, manifestList.Manifests, len(platforms))
+	require.Empty(t, pushResults, "no platform leg should push on its own; only the manifest list should be pushed")
+
+	wantDigest, err := reg.ManifestDigest("build-multiplatform-push-test", "latest")
+	require.NoError(t, err)
+	require.Equal(t, wantDigest, manifestList.ListDigest, "pushed manifest list digest must match what the registry has")
+}
+
+// buildContainerIdsFilter collects every intermediate container ID reported
+// by the build's structured vertex events, rather than regex-scraping the
+// old " ---> Running in <id>" stream text.
 func buildContainerIdsFilter(buildOutput io.Reader) (filters.Args, error) {
-	const intermediateContainerPrefix = " ---> Running in "
 	filter := filters.NewArgs()
 
 	dec := json.NewDecoder(buildOutput)
@@ -123,8 +541,8 @@ func buildContainerIdsFilter(buildOutput io.Reader) (filters.Args, error) {
 		if err != nil {
 			return filter, err
 		}
-		if ix := strings.Index(m.Stream, intermediateContainerPrefix); ix != -1 {
-			filter.Add("id", strings.TrimSpace(m.Stream[ix+len(intermediateContainerPrefix):]))
+		if v, ok := jsonmessage.VertexFromAux(m); ok && v.ContainerID != "" {
+			filter.Add("id", v.ContainerID)
 		}
 	}
 }
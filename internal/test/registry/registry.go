@@ -0,0 +1,94 @@
+package registry // import "github.com/docker/docker/internal/test/registry"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+// V2 wraps a throwaway `registry:2` container used by integration tests that
+// need a real push/pull target.
+type V2 struct {
+	URL         string
+	containerID string
+}
+
+const registryPort nat.Port = "5000/tcp"
+
+// NewV2 starts a local v2 registry container and returns once it answers
+// health checks on its API root.
+func NewV2(t *testing.T) *V2 {
+	t.Helper()
+	ctx := context.Background()
+	apiClient, err := client.NewClientWithOpts(client.FromEnv)
+	require.NoError(t, err)
+
+	resp, err := apiClient.ContainerCreate(ctx,
+		&container.Config{
+			Image:        "registry:2",
+			ExposedPorts: nat.PortSet{registryPort: {}},
+		},
+		&container.HostConfig{PublishAllPorts: true},
+		nil, nil, "")
+	require.NoError(t, err)
+	require.NoError(t, apiClient.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}))
+
+	reg := &V2{containerID: resp.ID}
+	inspect, err := apiClient.ContainerInspect(ctx, resp.ID)
+	require.NoError(t, err)
+	bindings := inspect.NetworkSettings.Ports[registryPort]
+	require.NotEmpty(t, bindings)
+	reg.URL = fmt.Sprintf("localhost:%s", bindings[0].HostPort)
+
+	require.NoError(t, reg.waitReady())
+	return reg
+}
+
+func (r *V2) waitReady() error {
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get("http://" + r.URL + "/v2/")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("registry at %s did not become ready", r.URL)
+}
+
+// ManifestDigest fetches the current digest the registry reports for
+// repo:tag, so tests can assert the daemon pushed what it claimed to.
+func (r *V2) ManifestDigest(repo, tag string) (string, error) {
+	req, err := http.NewRequest(http.MethodHead, fmt.Sprintf("http://%s/v2/%s/manifests/%s", r.URL, repo, tag), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json")
+	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.list.v2+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// Close removes the registry container.
+func (r *V2) Close(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	apiClient, err := client.NewClientWithOpts(client.FromEnv)
+	require.NoError(t, err)
+	require.NoError(t, apiClient.ContainerRemove(ctx, r.containerID, types.ContainerRemoveOptions{Force: true}))
+}
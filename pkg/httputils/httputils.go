@@ -0,0 +1,22 @@
+package httputils // import "github.com/docker/docker/pkg/httputils"
+
+import (
+	"net/http"
+	"strings"
+)
+
+// BoolValue transforms a form value into a boolean, taking into account
+// Docker's legacy spelling of `1` as "true".
+func BoolValue(r *http.Request, k string) bool {
+	s := strings.ToLower(strings.TrimSpace(r.FormValue(k)))
+	return !(s == "" || s == "0" || s == "no" || s == "false" || s == "none")
+}
+
+// BoolValueOrDefault returns the default bool passed if the query param is
+// missing, otherwise it returns the query parameter as a bool.
+func BoolValueOrDefault(r *http.Request, k string, d bool) bool {
+	if _, ok := r.Form[k]; !ok {
+		return d
+	}
+	return BoolValue(r, k)
+}
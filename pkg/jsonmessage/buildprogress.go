@@ -0,0 +1,97 @@
+package jsonmessage // import "github.com/docker/docker/pkg/jsonmessage"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DisplayBuildProgress reads a stream of JSONMessage values from in and
+// renders each BuildVertex event to out as a per-step progress line with
+// elapsed time, in place of the old convention of printing raw `Stream`
+// text and leaving clients to scrape it for step boundaries.
+func DisplayBuildProgress(out io.Writer, in io.Reader) error {
+	dec := json.NewDecoder(in)
+	started := map[string]time.Time{}
+	for {
+		var m JSONMessage
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		v, ok := VertexFromAux(m)
+		if !ok {
+			if m.Stream != "" {
+				fmt.Fprint(out, m.Stream)
+			}
+			continue
+		}
+
+		switch {
+		case v.Error != "":
+			fmt.Fprintf(out, "[%s] %s: error: %s\n", v.ID, v.Name, v.Error)
+		case v.Completed != "":
+			elapsed := ""
+			if start, ok := started[v.ID]; ok {
+				elapsed = time.Since(start).Round(time.Millisecond).String()
+			}
+			state := "done"
+			if v.Cached {
+				state = "cached"
+			}
+			fmt.Fprintf(out, "[%s] %s: %s %s\n", v.ID, v.Name, state, elapsed)
+		default:
+			started[v.ID] = time.Now()
+			fmt.Fprintf(out, "[%s] %s: running\n", v.ID, v.Name)
+		}
+	}
+}
+
+// NewLegacyStreamReader adapts a structured vertex-event stream back into
+// the old plain-text `Stream` convention (` ---> Running in <id>`), for
+// callers that haven't moved off it yet.
+func NewLegacyStreamReader(in io.Reader) io.Reader {
+	r, w := io.Pipe()
+	go func() {
+		dec := json.NewDecoder(in)
+		enc := json.NewEncoder(w)
+		for {
+			var m JSONMessage
+			if err := dec.Decode(&m); err != nil {
+				if err == io.EOF {
+					w.Close()
+				} else {
+					w.CloseWithError(err)
+				}
+				return
+			}
+
+			v, ok := VertexFromAux(m)
+			if !ok {
+				if err := enc.Encode(m); err != nil {
+					w.CloseWithError(err)
+					return
+				}
+				continue
+			}
+			// Only the dedicated "container started" event (ContainerID
+			// set, not yet completed) maps onto the old stream line.
+			// StepStarted carries no container ID yet, and
+			// StepCompleted/StepFailed always set Completed, so neither
+			// should produce a legacy line here.
+			if v.ContainerID == "" || v.Completed != "" {
+				continue
+			}
+			legacy := JSONMessage{Stream: fmt.Sprintf(" ---> Running in %s\n", v.ContainerID)}
+			if err := enc.Encode(legacy); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return r
+}
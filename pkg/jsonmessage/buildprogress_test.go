@@ -0,0 +1,45 @@
+package jsonmessage // import "github.com/docker/docker/pkg/jsonmessage"
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLegacyStreamReader(t *testing.T) {
+	var stream bytes.Buffer
+	enc := json.NewEncoder(&stream)
+
+	encodeVertex := func(v BuildVertex) {
+		raw, err := json.Marshal(v)
+		require.NoError(t, err)
+		rawMsg := json.RawMessage(raw)
+		require.NoError(t, enc.Encode(JSONMessage{Aux: &rawMsg}))
+	}
+
+	encodeVertex(BuildVertex{Type: "vertex", ID: "stage-0/step-1", Name: "RUN exit 0", Started: "t0"})
+	encodeVertex(BuildVertex{Type: "vertex", ID: "stage-0/step-1", Name: "RUN exit 0", ContainerID: "abc123"})
+	encodeVertex(BuildVertex{Type: "vertex", ID: "stage-0/step-1", Name: "RUN exit 0", Completed: "t1", ContainerID: "abc123"})
+	require.NoError(t, enc.Encode(JSONMessage{Stream: "Step 1/1 : RUN exit 0\n"}))
+
+	legacy := NewLegacyStreamReader(&stream)
+	dec := json.NewDecoder(legacy)
+
+	var messages []JSONMessage
+	for {
+		var m JSONMessage
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		messages = append(messages, m)
+	}
+
+	require.Len(t, messages, 2)
+	require.Equal(t, " ---> Running in abc123\n", messages[0].Stream)
+	require.Equal(t, "Step 1/1 : RUN exit 0\n", messages[1].Stream)
+}
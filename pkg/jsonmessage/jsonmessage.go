@@ -0,0 +1,168 @@
+package jsonmessage // import "github.com/docker/docker/pkg/jsonmessage"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// JSONError wraps a concrete Code and Message, `Code` is
+// is an integer error code, `Message` is the error message.
+type JSONError struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (e *JSONError) Error() string {
+	return e.Message
+}
+
+// JSONProgress describes a Progress. terminalFd is the fd of the current terminal,
+// if any, that this JSONProgress is being rendered to.
+type JSONProgress struct {
+	terminalFd uintptr
+	Current    int64  `json:"current,omitempty"`
+	Total      int64  `json:"total,omitempty"`
+	Start      int64  `json:"start,omitempty"`
+	// If true, don't show xB/yB
+	HideCounts bool   `json:"hidecounts,omitempty"`
+	Units      string `json:"units,omitempty"`
+	nowFunc    func() time.Time
+	winSize    int
+}
+
+// BuildInfoSource describes a single resource that was consumed while
+// producing an image: the base image a stage was built `FROM`, a remote
+// `ADD <url>`, or the git context the build itself was fetched from.
+type BuildInfoSource struct {
+	// Type identifies the kind of source, e.g. "docker-image", "http" or "git".
+	Type string `json:"type"`
+	// Ref is the reference as written in the Dockerfile or build request,
+	// e.g. "docker.io/library/busybox:latest" or the ADD URL.
+	Ref string `json:"ref"`
+	// Pin is the resolved, content-addressable identifier for Ref: the
+	// image digest, the downloaded content's digest, or the resolved
+	// git commit SHA.
+	Pin string `json:"pin"`
+}
+
+// BuildInfo is the provenance manifest emitted at the end of a successful
+// build, summarizing every external source the build consumed.
+type BuildInfo struct {
+	Sources []BuildInfoSource `json:"sources"`
+}
+
+// BuildVertex describes the start, completion, or failure of a single
+// Dockerfile step. It is carried as the `aux` payload of a JSONMessage
+// (discriminated by Type == "vertex"), replacing regex-scraping of
+// `Stream` for intermediate container IDs and step timing.
+type BuildVertex struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Started     string `json:"started,omitempty"`
+	Completed   string `json:"completed,omitempty"`
+	Cached      bool   `json:"cached,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ContainerID string `json:"containerID,omitempty"`
+}
+
+// VertexFromAux attempts to decode m's Aux payload as a BuildVertex. ok is
+// false if m carries no aux data, or the aux data isn't a vertex event.
+func VertexFromAux(m JSONMessage) (v BuildVertex, ok bool) {
+	if m.Aux == nil {
+		return v, false
+	}
+	if err := json.Unmarshal(*m.Aux, &v); err != nil || v.Type != "vertex" {
+		return BuildVertex{}, false
+	}
+	return v, true
+}
+
+// JSONMessage defines a message struct used for JSON streaming output.
+type JSONMessage struct {
+	Stream          string        `json:"stream,omitempty"`
+	Status          string        `json:"status,omitempty"`
+	Progress        *JSONProgress `json:"progressDetail,omitempty"`
+	ProgressMessage string        `json:"progress,omitempty"` // deprecated
+	ID              string        `json:"id,omitempty"`
+	From            string        `json:"from,omitempty"`
+	Time            int64         `json:"time,omitempty"`
+	TimeNano        int64         `json:"timeNano,omitempty"`
+	Error           *JSONError    `json:"errorDetail,omitempty"`
+	ErrorMessage    string        `json:"error,omitempty"` // deprecated
+	// Aux contains out-of-band data, such as the final image digest for a build.
+	Aux *json.RawMessage `json:"aux,omitempty"`
+	// BuildInfo carries the provenance manifest for a build, set on the
+	// "Successfully built <id>" message of a successful `ImageBuild` stream.
+	// A push result or manifest list aux message may follow it.
+	BuildInfo *BuildInfo `json:"buildInfo,omitempty"`
+}
+
+func (jm *JSONMessage) Display(out io.Writer, isTerminal bool) error {
+	if jm.Error != nil {
+		if jm.Error.Code == 401 {
+			return fmt.Errorf("authentication is required")
+		}
+		return jm.Error
+	}
+	var endl string
+	if isTerminal && (jm.Progress != nil || jm.ProgressMessage != "") {
+		endl = "\r"
+		fmt.Fprint(out, endl)
+	}
+	if jm.Time != 0 {
+		fmt.Fprintf(out, "%s ", time.Unix(jm.Time, 0).Format(jsonTimeFormat))
+	}
+	if jm.TimeNano != 0 {
+		fmt.Fprintf(out, "%s ", time.Unix(0, jm.TimeNano).Format(jsonTimeFormat))
+	}
+	if jm.ID != "" {
+		fmt.Fprintf(out, "%s: ", jm.ID)
+	}
+	if jm.From != "" {
+		fmt.Fprintf(out, "(from %s) ", jm.From)
+	}
+	if jm.Progress != nil {
+		fmt.Fprintf(out, "%s %s%s", jm.Status, jm.Progress.String(), endl)
+	} else if jm.ProgressMessage != "" {
+		fmt.Fprintf(out, "%s %s%s", jm.Status, jm.ProgressMessage, endl)
+	} else if jm.Stream != "" {
+		fmt.Fprintf(out, "%s%s", jm.Stream, endl)
+	} else {
+		fmt.Fprintf(out, "%s%s\n", jm.Status, endl)
+	}
+	return nil
+}
+
+const jsonTimeFormat = "2006-01-02T15:04:05.999999999-07:00"
+
+func (p *JSONProgress) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d/%d", p.Current, p.Total))
+	return sb.String()
+}
+
+// DisplayJSONMessagesStream reads a JSON message stream from in, and writes
+// each message's rendered form to out.
+func DisplayJSONMessagesStream(in io.Reader, out io.Writer, terminalFd uintptr, isTerminal bool, auxCallback func(JSONMessage)) error {
+	dec := json.NewDecoder(in)
+	for {
+		var jm JSONMessage
+		if err := dec.Decode(&jm); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if jm.Aux != nil && auxCallback != nil {
+			auxCallback(jm)
+			continue
+		}
+		if err := jm.Display(out, isTerminal); err != nil {
+			return err
+		}
+	}
+}